@@ -0,0 +1,157 @@
+package visionsvc
+
+import (
+	"context"
+	"image"
+
+	"github.com/pkg/errors"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/vision"
+)
+
+// FilterModel is the model for the companion cropping-filter-camera component.
+var FilterModel = resource.NewModel("viam-soleng", "camera", "cropping-filter-camera")
+var FilterPrettyName = "Viam cropping filter camera"
+var FilterDescription = "A camera that crops frames down to a passing detection so data capture only stores the interesting part of the image"
+
+// FilterConfig configures the cropping-filter-camera component. It reuses the
+// same detector knobs as Config so the two resources can be tuned consistently.
+type FilterConfig struct {
+	Camera             string   `json:"camera"`
+	Detector           string   `json:"detector"`
+	DetectorConfidence float64  `json:"detector_confidence"`
+	DetectorLabels     []string `json:"detector_labels"`
+	DetPadding         int      `json:"padding"`
+
+	// NMSIoUThreshold is the IoU at or above which an overlapping, lower-scored
+	// detection is suppressed. Defaults to 0.5.
+	NMSIoUThreshold float64 `json:"nms_iou_threshold"`
+	// NMSMode is "per_label" (only suppress overlaps sharing a label, the default)
+	// or "class_agnostic" (suppress any overlapping box regardless of label).
+	NMSMode string `json:"nms_mode"`
+}
+
+func (cfg *FilterConfig) Validate(path string) ([]string, error) {
+	if cfg.Camera == "" {
+		return nil, errors.New("camera is required")
+	}
+	if cfg.Detector == "" {
+		return nil, errors.New("detector is required")
+	}
+	if cfg.DetectorConfidence <= 0.0 {
+		return nil, errors.New("detector_confidence must be > 0.0")
+	}
+	return []string{cfg.Camera, cfg.Detector}, nil
+}
+
+// cropFilterCamera wraps an underlying camera and, during data capture, only
+// yields frames that contain a passing detection, cropped down to the padded
+// bounding box. Outside of data capture it passes the source frame through
+// unfiltered so live viewing (e.g. the Control tab) is unaffected.
+type cropFilterCamera struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger             logging.Logger
+	camera             camera.Camera
+	detector           vision.Service
+	detectorConfidence float64
+	detectorLabels     []string
+	detPadding         int
+	nmsIoUThreshold    float64
+	nmsMode            string
+}
+
+func init() {
+	resource.RegisterComponent(
+		camera.API,
+		FilterModel,
+		resource.Registration[camera.Camera, *FilterConfig]{
+			Constructor: newFilterCamera,
+		})
+}
+
+func newFilterCamera(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (camera.Camera, error) {
+	logger.Debugf("Starting %s", FilterPrettyName)
+	newConf, err := resource.NativeConfig[*FilterConfig](conf)
+	if err != nil {
+		return nil, err
+	}
+	srcCamera, err := camera.FromDependencies(deps, newConf.Camera)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get source camera %v", newConf.Camera)
+	}
+	detector, err := vision.FromDependencies(deps, newConf.Detector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get Object Detector %v", newConf.Detector)
+	}
+	nmsIoUThreshold := newConf.NMSIoUThreshold
+	if nmsIoUThreshold == 0 {
+		nmsIoUThreshold = 0.5
+	}
+	nmsMode := newConf.NMSMode
+	if nmsMode == "" {
+		nmsMode = "per_label"
+	}
+	filter := &cropFilterCamera{
+		Named:              conf.ResourceName().AsNamed(),
+		logger:             logger,
+		camera:             srcCamera,
+		detector:           detector,
+		detectorConfidence: newConf.DetectorConfidence,
+		detectorLabels:     newConf.DetectorLabels,
+		detPadding:         newConf.DetPadding,
+		nmsIoUThreshold:    nmsIoUThreshold,
+		nmsMode:            nmsMode,
+	}
+	src, err := camera.NewVideoSourceFromReader(ctx, filter, nil, camera.ColorStream)
+	if err != nil {
+		return nil, err
+	}
+	return camera.FromVideoSource(conf.ResourceName(), src), nil
+}
+
+// Read implements camera.VideoReader. Outside of a data-management capture call
+// it passes the source frame through unfiltered; during data capture it runs the
+// same NMS-aware detect+filter selection as myVisionSvc and, if a detection
+// passes, returns the frame cropped to its padded bounding box. Otherwise it
+// returns data.ErrNoCaptureToStore so the data management subsystem drops the frame.
+func (cf *cropFilterCamera) Read(ctx context.Context) (image.Image, func(), error) {
+	stream, err := cf.camera.Stream(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	img, release, err := stream.Next(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fromDM, ok := ctx.Value(data.FromDMContextKey{}).(bool)
+	if !ok || !fromDM {
+		return img, release, nil
+	}
+	defer release()
+
+	detections, _, err := selectDetections(
+		ctx, img, cf.detector, cf.detectorConfidence, cf.detectorLabels,
+		1, cf.nmsIoUThreshold, cf.nmsMode,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(detections) == 0 {
+		return nil, nil, data.ErrNoCaptureToStore
+	}
+	croppedImg, _, err := cropDetection(img, detections[0], cf.detPadding)
+	if err != nil {
+		return nil, nil, err
+	}
+	return croppedImg, func() {}, nil
+}
+
+// Close shuts down the underlying source camera.
+func (cf *cropFilterCamera) Close(ctx context.Context) error {
+	return cf.camera.Close(ctx)
+}
@@ -4,27 +4,36 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"image"
 	"image/draw"
 	"image/jpeg"
+	"math"
 	"os"
+	"runtime"
 	"slices"
 	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/golang/geo/r3"
 	"github.com/pkg/errors"
 	"go.viam.com/rdk/components/camera"
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/pointcloud"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
 	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/spatialmath"
 	"go.viam.com/rdk/utils"
 	vis "go.viam.com/rdk/vision"
 	"go.viam.com/rdk/vision/classification"
 	"go.viam.com/rdk/vision/objectdetection"
+	"golang.org/x/sync/errgroup"
 )
 
-var errUnimplemented = errors.New("unimplemented")
 var Model = resource.NewModel("viam-soleng", "vision", "detect-and-classify")
 var PrettyName = "Viam detect and classify vision service"
 var Description = "A module of the Viam vision service that crops an image to an initial detection then runs other models to return detections"
@@ -36,14 +45,52 @@ type Config struct {
 	MaxDetections      int      `json:"max_detections"`
 	DetectorLabels     []string `json:"detector_labels"`
 	DetPadding         int      `json:"padding"`
-	Classifier1        string   `json:"classifier1"`
-	Classifier2        string   `json:"classifier2"`
-	MaxClassifications int      `json:"max_classifications"`
 	LogImage           bool     `json:"log_image"`
 	ImagePath          string   `json:"image_path"`
 
-	// TODO: Implement list of classifiers -> https://github.com/viamrobotics/rdk/blob/main/components/camera/transformpipeline/transform.go#L110
+	// CombineLabels, when true, makes Detections return "<det_label>/<cls_label>"
+	// instead of just the top classifier label.
+	CombineLabels bool `json:"combine_labels"`
+	// ScoreFusion controls how Detections combines the detector score with the
+	// top classifier score: "classifier" (default), "product", "min", or
+	// "weighted_average" (see DetectorWeight).
+	ScoreFusion string `json:"score_fusion"`
+	// DetectorWeight is the detector's share of the score when ScoreFusion is
+	// "weighted_average"; the classifier gets the remaining 1-DetectorWeight.
+	DetectorWeight float64 `json:"detector_weight"`
+
+	// Deprecated: set Pipeline instead. When Pipeline is empty, a pipeline with one
+	// entry per non-empty Classifier1/Classifier2 field is synthesized for back-compat.
+	Classifier1 string `json:"classifier1"`
+	// Deprecated: set Pipeline instead.
+	Classifier2 string `json:"classifier2"`
+	// Deprecated: set a per-entry "top_k" attribute on the last Pipeline entry instead.
+	MaxClassifications int `json:"max_classifications"`
+
+	// Pipeline is the ordered list of classifiers run against each cropped detection.
 	Pipeline []Classification `json:"classifiers"`
+
+	// DepthCamera, when set, enables GetObjectPointClouds by pairing each color
+	// frame from Camera with a synchronized depth frame from this camera.
+	DepthCamera string `json:"depth_camera"`
+	// MinDepthSamples is the minimum number of valid depth samples a detection's
+	// crop must contain to be lifted into a point cloud object; otherwise it is skipped.
+	MinDepthSamples int `json:"min_depth_samples"`
+
+	// MaxConcurrentInferences bounds how many (detection, pipeline stage) classifier
+	// calls run at once. Defaults to GOMAXPROCS when unset.
+	MaxConcurrentInferences int `json:"max_concurrent_inferences"`
+
+	// NMSIoUThreshold is the IoU at or above which an overlapping, lower-scored
+	// detection is suppressed. Defaults to 0.5.
+	NMSIoUThreshold float64 `json:"nms_iou_threshold"`
+	// NMSMode is "per_label" (only suppress overlaps sharing a label, the default)
+	// or "class_agnostic" (suppress any overlapping box regardless of label).
+	NMSMode string `json:"nms_mode"`
+
+	// CropRingBufferSize is how many recent cropped frames "dump_last_crops" can
+	// return; only populated while LogImage is true. Defaults to 20.
+	CropRingBufferSize int `json:"crop_ring_buffer_size"`
 }
 
 // Classification configuration type.
@@ -51,24 +98,47 @@ type Classification struct {
 	Classifier string             `json:"classifier"`
 	Attributes utils.AttributeMap `json:"attributes"`
 }
+
+// pipelineStage is a resolved, ready-to-call entry of the classifier pipeline.
+type pipelineStage struct {
+	name          string
+	service       vision.Service
+	topK          int
+	minConfidence float64
+	labelFilter   []string
+	labelPrefix   string
+}
+
 type myVisionSvc struct {
 	resource.Named
-	logger             logging.Logger
-	camera             camera.Camera
-	detector           vision.Service
-	detectorConfidence float64
-	maxDetections      int
-	detectorLabels     []string
-	detPadding         int
-	classifier         vision.Service
-	classifier2        vision.Service
-	maxClassifications int
-	logImage           bool
-	imagePath          string
-	mu                 sync.RWMutex
-	cancelCtx          context.Context
-	cancelFunc         func()
-	done               chan bool
+	logger                  logging.Logger
+	camera                  camera.Camera
+	detector                vision.Service
+	detectorConfidence      float64
+	maxDetections           int
+	detectorLabels          []string
+	detPadding              int
+	pipeline                []pipelineStage
+	maxClassifications      int
+	combineLabels           bool
+	scoreFusion             string
+	detectorWeight          float64
+	depthCamera             camera.Camera
+	minDepthSamples         int
+	maxConcurrentInferences int
+	nmsIoUThreshold         float64
+	nmsMode                 string
+	logImage                bool
+	imagePath               string
+	cropRing                *cropRingBuffer
+	stats                   *latencyStats
+	framesProcessed         int64
+	detectionsKept          int64
+	detectionsSuppressed    int64
+	mu                      sync.RWMutex
+	cancelCtx               context.Context
+	cancelFunc              func()
+	done                    chan bool
 }
 
 func init() {
@@ -91,6 +161,7 @@ func newService(ctx context.Context, deps resource.Dependencies, conf resource.C
 		cancelFunc: cancelFunc,
 		mu:         sync.RWMutex{},
 		done:       make(chan bool),
+		stats:      newLatencyStats(),
 	}
 
 	if err := svc.Reconfigure(ctx, deps, conf); err != nil {
@@ -109,18 +180,42 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 	if cfg.DetectorConfidence <= 0.0 {
 		return nil, errors.New("detector_confidence must be >= 0.0")
 	}
-	if cfg.Classifier1 == "" {
-		return nil, errors.New("classifier1_service is required")
+	pipeline := cfg.Pipeline
+	if len(pipeline) == 0 {
+		pipeline = synthesizePipeline(cfg)
+	}
+	if len(pipeline) == 0 {
+		return nil, errors.New("at least one pipeline classifier entry (or classifier1) is required")
 	}
-	if cfg.Classifier2 == "" {
-		return nil, errors.New("classifier2_service is required")
+	deps := []string{cfg.Camera, cfg.Detector}
+	seen := make(map[string]bool, len(pipeline))
+	for _, entry := range pipeline {
+		if entry.Classifier == "" {
+			return nil, errors.New("pipeline entry is missing a classifier name")
+		}
+		if seen[entry.Classifier] {
+			return nil, errors.Errorf("duplicate classifier %q in pipeline", entry.Classifier)
+		}
+		seen[entry.Classifier] = true
+		deps = append(deps, entry.Classifier)
 	}
-	/* TODO: Deactivated until list of classifier is implemented
-	if cfg.MaxClassifications == 0 {
-		return nil, errors.New("max_classifications must be > 0")
+	if cfg.DepthCamera != "" {
+		deps = append(deps, cfg.DepthCamera)
 	}
-	*/
-	return []string{cfg.Camera, cfg.Detector, cfg.Classifier1, cfg.Classifier2}, nil
+	return deps, nil
+}
+
+// synthesizePipeline builds a pipeline of up to two entries, one for each of the
+// deprecated Classifier1/Classifier2 fields that is set, so existing configs keep working.
+func synthesizePipeline(cfg *Config) []Classification {
+	var pipeline []Classification
+	if cfg.Classifier1 != "" {
+		pipeline = append(pipeline, Classification{Classifier: cfg.Classifier1})
+	}
+	if cfg.Classifier2 != "" {
+		pipeline = append(pipeline, Classification{Classifier: cfg.Classifier2})
+	}
+	return pipeline
 }
 
 // Reconfigure reconfigures with new settings.
@@ -146,21 +241,63 @@ func (svc *myVisionSvc) Reconfigure(ctx context.Context, deps resource.Dependenc
 	}
 	// Get the detector confidence threshold
 	svc.detectorConfidence = newConf.DetectorConfidence
-	// Get the classifiers
-	svc.classifier, err = vision.FromDependencies(deps, newConf.Classifier1)
-	if err != nil {
-		return errors.Wrapf(err, "unable to get classifier %v ", newConf.Classifier1)
+	// Resolve the classifier pipeline, falling back to the deprecated
+	// classifier1/classifier2 fields when no pipeline is configured.
+	pipelineConf := newConf.Pipeline
+	if len(pipelineConf) == 0 {
+		pipelineConf = synthesizePipeline(newConf)
 	}
-	svc.classifier2, err = vision.FromDependencies(deps, newConf.Classifier2)
-	if err != nil {
-		return errors.Wrapf(err, "unable to get classifier2 %v ", newConf.Classifier2)
+	pipeline := make([]pipelineStage, 0, len(pipelineConf))
+	for _, entry := range pipelineConf {
+		svcDep, err := vision.FromDependencies(deps, entry.Classifier)
+		if err != nil {
+			return errors.Wrapf(err, "unable to get classifier %v ", entry.Classifier)
+		}
+		pipeline = append(pipeline, pipelineStage{
+			name:          entry.Classifier,
+			service:       svcDep,
+			topK:          entry.Attributes.Int("top_k", 1),
+			minConfidence: entry.Attributes.Float64("min_confidence", 0),
+			labelFilter:   entry.Attributes.StringSlice("label_filter"),
+			labelPrefix:   entry.Attributes.String("label_prefix"),
+		})
+	}
+	svc.pipeline = pipeline
+	// Get the optional depth camera used by GetObjectPointClouds
+	svc.depthCamera = nil
+	if newConf.DepthCamera != "" {
+		svc.depthCamera, err = camera.FromDependencies(deps, newConf.DepthCamera)
+		if err != nil {
+			return errors.Wrapf(err, "unable to get depth camera %v", newConf.DepthCamera)
+		}
+	}
+	svc.minDepthSamples = newConf.MinDepthSamples
+	if svc.minDepthSamples <= 0 {
+		svc.minDepthSamples = 1
+	}
+	svc.maxConcurrentInferences = newConf.MaxConcurrentInferences
+	svc.nmsIoUThreshold = newConf.NMSIoUThreshold
+	if svc.nmsIoUThreshold == 0 {
+		svc.nmsIoUThreshold = 0.5
+	}
+	svc.nmsMode = newConf.NMSMode
+	if svc.nmsMode == "" {
+		svc.nmsMode = "per_label"
 	}
 	svc.detPadding = newConf.DetPadding
 	svc.maxDetections = newConf.MaxDetections
 	svc.detectorLabels = newConf.DetectorLabels
 	svc.logImage = newConf.LogImage
 	svc.imagePath = newConf.ImagePath
+	ringSize := newConf.CropRingBufferSize
+	if ringSize <= 0 {
+		ringSize = 20
+	}
+	svc.cropRing = newCropRingBuffer(ringSize)
 	svc.maxClassifications = newConf.MaxClassifications
+	svc.combineLabels = newConf.CombineLabels
+	svc.scoreFusion = newConf.ScoreFusion
+	svc.detectorWeight = newConf.DetectorWeight
 	svc.logger.Debug("**** Reconfigured ****")
 	return nil
 }
@@ -186,22 +323,240 @@ func (svc *myVisionSvc) ClassificationsFromCamera(ctx context.Context, cameraNam
 	return svc.detectAndClassify(ctx, img)
 }
 
+// Detections runs the configured detector over img and, for each kept detection,
+// runs the classifier pipeline against the cropped region to produce a cascaded
+// two-stage detection.
 func (svc *myVisionSvc) Detections(ctx context.Context, img image.Image, extra map[string]interface{}) ([]objectdetection.Detection, error) {
-	return nil, errUnimplemented
+	return svc.detectCascade(ctx, img)
 }
 
-func (svc *myVisionSvc) DetectionsFromCamera(ctx context.Context, camera string, extra map[string]interface{}) ([]objectdetection.Detection, error) {
-	return nil, errUnimplemented
+// DetectionsFromCamera is the camera-sourced counterpart to Detections.
+func (svc *myVisionSvc) DetectionsFromCamera(ctx context.Context, cameraName string, extra map[string]interface{}) ([]objectdetection.Detection, error) {
+	// gets the stream from a camera
+	stream, err := svc.camera.Stream(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	// gets an image from the camera stream
+	img, release, err := stream.Next(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return svc.detectCascade(ctx, img)
 }
 
-// ObjectPointClouds can be implemented to extend functionality but returns unimplemented currently.
-func (s *myVisionSvc) GetObjectPointClouds(ctx context.Context, cameraName string, extra map[string]interface{}) ([]*vis.Object, error) {
-	return nil, errUnimplemented
+// GetObjectPointClouds lifts each kept 2D detection into 3D by pairing the color
+// image with a synchronized depth frame from the configured depth_camera and
+// projecting the pixels inside the padded bounding box through its intrinsics.
+func (svc *myVisionSvc) GetObjectPointClouds(ctx context.Context, cameraName string, extra map[string]interface{}) ([]*vis.Object, error) {
+	atomic.AddInt64(&svc.framesProcessed, 1)
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	if svc.depthCamera == nil {
+		return nil, errors.New("depth_camera must be configured to use GetObjectPointClouds")
+	}
+	colorStream, err := svc.camera.Stream(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	colorImg, colorRelease, err := colorStream.Next(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer colorRelease()
+
+	depthStream, err := svc.depthCamera.Stream(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	depthImg, depthRelease, err := depthStream.Next(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer depthRelease()
+	depthMap, err := rimage.ConvertImageToDepthMap(ctx, depthImg)
+	if err != nil {
+		return nil, err
+	}
+
+	props, err := svc.depthCamera.Properties(ctx)
+	if err != nil {
+		return nil, err
+	}
+	intrinsics, err := rimage.IntrinsicsFromProperties(props)
+	if err != nil {
+		return nil, err
+	}
+
+	detections, err := svc.selectDetections(ctx, colorImg)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]*vis.Object, 0, len(detections))
+	for _, detection := range detections {
+		croppedImg, rectangle, err := svc.cropDetection(colorImg, detection)
+		if err != nil {
+			return nil, err
+		}
+		rectangle = rectangle.Intersect(depthMap.Bounds())
+
+		cloud := pointcloud.NewBasicEmpty()
+		for y := rectangle.Min.Y; y < rectangle.Max.Y; y++ {
+			for x := rectangle.Min.X; x < rectangle.Max.X; x++ {
+				depthVal := depthMap.Get(image.Point{X: x, Y: y})
+				if depthVal == 0 {
+					continue
+				}
+				px, py, pz := intrinsics.PixelToPoint(float64(x), float64(y), float64(depthVal))
+				if err := cloud.Set(r3.Vector{X: px, Y: py, Z: pz}, pointcloud.NewBasicData()); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if cloud.Size() < svc.minDepthSamples {
+			svc.logger.Debugf("skipping detection %v: only %v valid depth samples", detection.Label(), cloud.Size())
+			continue
+		}
+
+		label := detection.Label()
+		if stageResult, err := svc.classifyCrop(ctx, croppedImg); err == nil && len(stageResult) > 0 {
+			sort.Slice(stageResult, func(i, j int) bool {
+				return stageResult[i].Score() > stageResult[j].Score()
+			})
+			label = stageResult[0].Label()
+		}
+
+		center, dims := centroidAndBounds(cloud)
+		geometry, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(center), dims, detection.Label())
+		if err != nil {
+			return nil, err
+		}
+		obj, err := vis.NewObjectWithLabel(cloud, label, geometry)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// centroidAndBounds returns the centroid and the axis-aligned bounding box
+// dimensions of every point in cloud.
+func centroidAndBounds(cloud pointcloud.PointCloud) (r3.Vector, r3.Vector) {
+	var sum r3.Vector
+	min := r3.Vector{X: math.Inf(1), Y: math.Inf(1), Z: math.Inf(1)}
+	max := r3.Vector{X: math.Inf(-1), Y: math.Inf(-1), Z: math.Inf(-1)}
+	n := 0
+	cloud.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		sum.X += p.X
+		sum.Y += p.Y
+		sum.Z += p.Z
+		min.X = math.Min(min.X, p.X)
+		min.Y = math.Min(min.Y, p.Y)
+		min.Z = math.Min(min.Z, p.Z)
+		max.X = math.Max(max.X, p.X)
+		max.Y = math.Max(max.Y, p.Y)
+		max.Z = math.Max(max.Z, p.Z)
+		n++
+		return true
+	})
+	centroid := r3.Vector{X: sum.X / float64(n), Y: sum.Y / float64(n), Z: sum.Z / float64(n)}
+	dims := r3.Vector{X: max.X - min.X, Y: max.Y - min.Y, Z: max.Z - min.Z}
+	return centroid, dims
+}
+
+// DoCommand implements a small runtime control/introspection surface so the
+// pipeline can be tuned and inspected from the Viam app's Control tab or from
+// scripts, without a full Reconfigure. Supported commands: "set_threshold",
+// "set_labels", "dump_last_crops", and "stats".
+func (svc *myVisionSvc) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	cmdName, ok := cmd["cmd"].(string)
+	if !ok {
+		return nil, errors.New(`DoCommand requires a string "cmd"`)
+	}
+	switch cmdName {
+	case "set_threshold":
+		return svc.doSetThreshold(cmd)
+	case "set_labels":
+		return svc.doSetLabels(cmd)
+	case "dump_last_crops":
+		return svc.doDumpLastCrops(cmd)
+	case "stats":
+		return svc.doStats(), nil
+	default:
+		return nil, errors.Errorf("unknown cmd %q", cmdName)
+	}
+}
+
+// doSetThreshold handles {"cmd":"set_threshold","value":0.6}.
+func (svc *myVisionSvc) doSetThreshold(cmd map[string]interface{}) (map[string]interface{}, error) {
+	value, ok := cmd["value"].(float64)
+	if !ok {
+		return nil, errors.New(`set_threshold requires a numeric "value"`)
+	}
+	svc.mu.Lock()
+	svc.detectorConfidence = value
+	svc.mu.Unlock()
+	return map[string]interface{}{"detector_confidence": value}, nil
+}
+
+// doSetLabels handles {"cmd":"set_labels","value":["cat","dog"]}.
+func (svc *myVisionSvc) doSetLabels(cmd map[string]interface{}) (map[string]interface{}, error) {
+	raw, ok := cmd["value"].([]interface{})
+	if !ok {
+		return nil, errors.New(`set_labels requires an array "value"`)
+	}
+	labels := make([]string, 0, len(raw))
+	for _, v := range raw {
+		label, ok := v.(string)
+		if !ok {
+			return nil, errors.New("set_labels value entries must be strings")
+		}
+		labels = append(labels, label)
+	}
+	svc.mu.Lock()
+	svc.detectorLabels = labels
+	svc.mu.Unlock()
+	return map[string]interface{}{"detector_labels": labels}, nil
+}
+
+// doDumpLastCrops handles {"cmd":"dump_last_crops","n":5}, returning up to n
+// most-recent cropped JPEGs (base64-encoded) from the in-memory ring buffer.
+func (svc *myVisionSvc) doDumpLastCrops(cmd map[string]interface{}) (map[string]interface{}, error) {
+	n := 5
+	if raw, ok := cmd["n"].(float64); ok {
+		n = int(raw)
+	}
+	svc.mu.RLock()
+	ring := svc.cropRing
+	svc.mu.RUnlock()
+	crops := ring.last(n)
+	encoded := make([]string, len(crops))
+	for i, c := range crops {
+		encoded[i] = base64.StdEncoding.EncodeToString(c)
+	}
+	return map[string]interface{}{"crops": encoded}, nil
 }
 
-// DoCommand can be implemented to extend functionality but returns unimplemented currently.
-func (s *myVisionSvc) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
-	return nil, errUnimplemented
+// doStats handles {"cmd":"stats"}, returning frame/detection counters and
+// per-stage classifier latency percentiles.
+func (svc *myVisionSvc) doStats() map[string]interface{} {
+	stageLatency := make(map[string]interface{})
+	for _, name := range svc.stats.stageNames() {
+		p50, p95 := svc.stats.percentiles(name)
+		stageLatency[name] = map[string]interface{}{
+			"p50_ms": float64(p50.Microseconds()) / 1000.0,
+			"p95_ms": float64(p95.Microseconds()) / 1000.0,
+		}
+	}
+	return map[string]interface{}{
+		"frames_processed":      atomic.LoadInt64(&svc.framesProcessed),
+		"detections_kept":       atomic.LoadInt64(&svc.detectionsKept),
+		"detections_suppressed": atomic.LoadInt64(&svc.detectionsSuppressed),
+		"stage_latency":         stageLatency,
+	}
 }
 
 // The close method is executed when the component is shut down
@@ -214,55 +569,22 @@ func (svc *myVisionSvc) Close(ctx context.Context) error {
 // Take an input image, detect objects, crop the image down to the detected bounding box and
 // hand over to classifier for more accurate classifications
 func (svc *myVisionSvc) detectAndClassify(ctx context.Context, img image.Image) (classification.Classifications, error) {
-	// Get detections from the provided Image
-	detections, err := svc.detector.Detections(ctx, img, nil)
+	atomic.AddInt64(&svc.framesProcessed, 1)
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	detections, err := svc.selectDetections(ctx, img)
 	if err != nil {
 		return nil, err
 	}
-	// sort detections based upon score
-	sort.Slice(detections, func(i, j int) bool {
-		return detections[i].Score() > detections[j].Score()
-	})
-	// trim detections based upon max detections setting / if detectorMaxDetections = 0 -> no limit
-	if len(detections) > svc.maxDetections && svc.maxDetections != 0 {
-		detections = detections[:svc.maxDetections]
+	_, stageResults, err := svc.classifyDetections(ctx, img, detections)
+	if err != nil {
+		return nil, err
 	}
-	svc.logger.Infof("Detections #: %v/%v", len(detections), svc.maxDetections)
-	svc.logger.Debugf("Detections Details: %v", detections)
 	// Result set to be returned
 	var classificationResult classification.Classifications
-	for _, detection := range detections {
-		// Check if the detection score is above the configured threshold
-		if detection.Score() >= svc.detectorConfidence && slices.Contains(svc.detectorLabels, detection.Label()) {
-			// Increase/decrease bounding box according to detection border setting
-			rectangle := image.Rect(
-				detection.BoundingBox().Min.X-svc.detPadding,
-				detection.BoundingBox().Min.Y-svc.detPadding,
-				detection.BoundingBox().Max.X+svc.detPadding,
-				detection.BoundingBox().Max.Y+svc.detPadding)
-			croppedImg, err := cropImage(img, &rectangle)
-			if err != nil {
-				return nil, err
-			}
-			// Save cropped images to disk
-			if svc.logImage {
-				err := saveImage(croppedImg, svc.imagePath)
-				if err != nil {
-					return nil, err
-				}
-			}
-			// Pass the cropped image to the classifier1 and get the classification with the highest confidence
-			classification, err := svc.classifier.Classifications(ctx, croppedImg, 1, nil)
-			if err != nil {
-				return nil, err
-			}
-			classificationResult = append(classificationResult, classification...)
-			// Pass the cropped image to the classifier2 and get the classification with the highest confidence
-			classification2, err := svc.classifier2.Classifications(ctx, croppedImg, 1, nil)
-			if err != nil {
-				return nil, err
-			}
-			classificationResult = append(classificationResult, classification2...)
+	for _, perDetection := range stageResults {
+		for _, stageResult := range perDetection {
+			classificationResult = append(classificationResult, stageResult...)
 		}
 	}
 	sort.Slice(classificationResult, func(i, j int) bool {
@@ -274,6 +596,282 @@ func (svc *myVisionSvc) detectAndClassify(ctx context.Context, img image.Image)
 	return classificationResult, nil
 }
 
+// detectCascade runs the detector then the classifier pipeline per kept detection,
+// turning each into a single fused objectdetection.Detection.
+func (svc *myVisionSvc) detectCascade(ctx context.Context, img image.Image) ([]objectdetection.Detection, error) {
+	atomic.AddInt64(&svc.framesProcessed, 1)
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	detections, err := svc.selectDetections(ctx, img)
+	if err != nil {
+		return nil, err
+	}
+	rectangles, stageResults, err := svc.classifyDetections(ctx, img, detections)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]objectdetection.Detection, 0, len(detections))
+	for i, detection := range detections {
+		var stageResult classification.Classifications
+		for _, sr := range stageResults[i] {
+			stageResult = append(stageResult, sr...)
+		}
+		label := detection.Label()
+		score := detection.Score()
+		if len(stageResult) > 0 {
+			sort.Slice(stageResult, func(i, j int) bool {
+				return stageResult[i].Score() > stageResult[j].Score()
+			})
+			top := stageResult[0]
+			if svc.combineLabels {
+				label = detection.Label() + "/" + top.Label()
+			} else {
+				label = top.Label()
+			}
+			score = fuseScore(svc.scoreFusion, detection.Score(), top.Score(), svc.detectorWeight)
+		}
+		result = append(result, objectdetection.NewDetection(rectangles[i], score, label))
+	}
+	return result, nil
+}
+
+// classifyDetections crops each detection and runs every pipeline stage against
+// it concurrently, bounded by max_concurrent_inferences (default GOMAXPROCS), and
+// ctx is propagated so the caller can cancel in-flight stages. Results are written
+// into a pre-sized per-(detection, stage) slice, so ordering is deterministic
+// once flattened by the caller. The caller must hold svc.mu for read across its
+// whole call, including selectDetections, so a concurrent Reconfigure can't swap
+// pipeline/detector handles out from under an in-flight request.
+func (svc *myVisionSvc) classifyDetections(
+	ctx context.Context, img image.Image, detections []objectdetection.Detection,
+) ([]image.Rectangle, [][]classification.Classifications, error) {
+	rectangles := make([]image.Rectangle, len(detections))
+	crops := make([]image.Image, len(detections))
+	for i, detection := range detections {
+		croppedImg, rectangle, err := svc.cropDetection(img, detection)
+		if err != nil {
+			return nil, nil, err
+		}
+		if svc.logImage {
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, croppedImg, nil); err != nil {
+				return nil, nil, err
+			}
+			svc.cropRing.add(buf.Bytes())
+			if err := saveImage(croppedImg, svc.imagePath); err != nil {
+				return nil, nil, err
+			}
+		}
+		rectangles[i] = rectangle
+		crops[i] = croppedImg
+	}
+
+	maxConcurrent := svc.maxConcurrentInferences
+	if maxConcurrent <= 0 {
+		maxConcurrent = runtime.GOMAXPROCS(0)
+	}
+	stageResults := make([][]classification.Classifications, len(detections))
+	for i := range stageResults {
+		stageResults[i] = make([]classification.Classifications, len(svc.pipeline))
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrent)
+	for detIdx, croppedImg := range crops {
+		for stageIdx, stage := range svc.pipeline {
+			detIdx, stageIdx, croppedImg, stage := detIdx, stageIdx, croppedImg, stage
+			g.Go(func() error {
+				start := time.Now()
+				stageResult, err := stage.service.Classifications(gctx, croppedImg, stage.topK, nil)
+				svc.stats.record(stage.name, time.Since(start))
+				if err != nil {
+					return err
+				}
+				stageResults[detIdx][stageIdx] = filterStageResult(stageResult, stage)
+				return nil
+			})
+		}
+	}
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+	return rectangles, stageResults, nil
+}
+
+// selectDetections delegates the detect+filter+NMS work to the package-level
+// selectDetections below (shared with cropFilterCamera) using svc's current
+// config, then records the kept/suppressed counters and logs. The caller must
+// hold svc.mu for read, since this reads detector/detectorConfidence/
+// detectorLabels/nms settings/maxDetections.
+func (svc *myVisionSvc) selectDetections(ctx context.Context, img image.Image) ([]objectdetection.Detection, error) {
+	kept, suppressed, err := selectDetections(
+		ctx, img, svc.detector, svc.detectorConfidence, svc.detectorLabels,
+		svc.maxDetections, svc.nmsIoUThreshold, svc.nmsMode,
+	)
+	if err != nil {
+		return nil, err
+	}
+	atomic.AddInt64(&svc.detectionsKept, int64(len(kept)))
+	atomic.AddInt64(&svc.detectionsSuppressed, int64(suppressed))
+	svc.logger.Infow("Detections", "kept", len(kept), "suppressed", suppressed, "max_detections", svc.maxDetections)
+	svc.logger.Debugf("Detections Details: %v", kept)
+	return kept, nil
+}
+
+// selectDetections runs detector over img, keeps only detections passing
+// confidence/labels, runs NMS to collapse duplicate boxes around the same
+// object, and caps the result at maxDetections so the cap counts distinct
+// objects rather than duplicates. It is package-level (rather than a
+// myVisionSvc method) so cropFilterCamera can share the same detect+filter+NMS
+// logic instead of re-implementing it.
+func selectDetections(
+	ctx context.Context, img image.Image, detector vision.Service, confidence float64,
+	labels []string, maxDetections int, nmsIoUThreshold float64, nmsMode string,
+) ([]objectdetection.Detection, int, error) {
+	detections, err := detector.Detections(ctx, img, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	// sort detections based upon score
+	sort.Slice(detections, func(i, j int) bool {
+		return detections[i].Score() > detections[j].Score()
+	})
+	var filtered []objectdetection.Detection
+	for _, detection := range detections {
+		// Check if the detection score is above the configured threshold
+		if detection.Score() >= confidence && slices.Contains(labels, detection.Label()) {
+			filtered = append(filtered, detection)
+		}
+	}
+	kept, suppressed := suppressOverlapping(filtered, nmsIoUThreshold, nmsMode)
+	// trim detections based upon max detections setting / if maxDetections = 0 -> no limit
+	if len(kept) > maxDetections && maxDetections != 0 {
+		kept = kept[:maxDetections]
+	}
+	return kept, suppressed, nil
+}
+
+// suppressOverlapping runs non-maximum suppression over detections, which must
+// already be sorted by descending score: a box is kept unless its IoU with an
+// already-kept box (same label, when nmsMode is "per_label") is at or above
+// nmsIoUThreshold. It returns the kept detections and the count suppressed.
+func suppressOverlapping(detections []objectdetection.Detection, nmsIoUThreshold float64, nmsMode string) ([]objectdetection.Detection, int) {
+	var kept []objectdetection.Detection
+	suppressed := 0
+	for _, candidate := range detections {
+		overlaps := false
+		for _, k := range kept {
+			if nmsMode == "per_label" && candidate.Label() != k.Label() {
+				continue
+			}
+			if intersectionOverUnion(candidate.BoundingBox(), k.BoundingBox()) >= nmsIoUThreshold {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			suppressed++
+			continue
+		}
+		kept = append(kept, candidate)
+	}
+	return kept, suppressed
+}
+
+// intersectionOverUnion computes area(intersection)/area(union) for two
+// rectangles via the inclusion-exclusion formula, guarding against zero-area boxes.
+func intersectionOverUnion(a, b image.Rectangle) float64 {
+	interArea := rectArea(a.Intersect(b))
+	unionArea := rectArea(a) + rectArea(b) - interArea
+	if unionArea <= 0 {
+		return 0
+	}
+	return float64(interArea) / float64(unionArea)
+}
+
+// rectArea returns r's area, or 0 for an empty (non-overlapping) rectangle.
+func rectArea(r image.Rectangle) int {
+	if r.Empty() {
+		return 0
+	}
+	return r.Dx() * r.Dy()
+}
+
+// cropDetection pads detection's bounding box per svc's configured padding and
+// delegates to the package-level cropDetection below.
+func (svc *myVisionSvc) cropDetection(img image.Image, detection objectdetection.Detection) (image.Image, image.Rectangle, error) {
+	return cropDetection(img, detection, svc.detPadding)
+}
+
+// cropDetection pads detection's bounding box by padding, clamps it to img's
+// bounds, and returns the cropped sub-image alongside the clamped rectangle.
+// It is package-level (rather than a myVisionSvc method) so cropFilterCamera
+// can share the same cropping logic instead of re-implementing it.
+func cropDetection(img image.Image, detection objectdetection.Detection, padding int) (image.Image, image.Rectangle, error) {
+	rectangle := image.Rect(
+		detection.BoundingBox().Min.X-padding,
+		detection.BoundingBox().Min.Y-padding,
+		detection.BoundingBox().Max.X+padding,
+		detection.BoundingBox().Max.Y+padding,
+	).Intersect(img.Bounds())
+	croppedImg, err := cropImage(img, &rectangle)
+	if err != nil {
+		return nil, image.Rectangle{}, err
+	}
+	return croppedImg, rectangle, nil
+}
+
+// classifyCrop runs every configured pipeline stage against croppedImg and
+// returns the combined, filtered classifications. The caller must hold svc.mu
+// for read, since this reads svc.pipeline.
+func (svc *myVisionSvc) classifyCrop(ctx context.Context, croppedImg image.Image) (classification.Classifications, error) {
+	var result classification.Classifications
+	for _, stage := range svc.pipeline {
+		stageResult, err := stage.service.Classifications(ctx, croppedImg, stage.topK, nil)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, filterStageResult(stageResult, stage)...)
+	}
+	return result, nil
+}
+
+// fuseScore combines a detector score and a classifier score per the configured
+// mode. Unknown/empty modes fall back to the classifier score alone.
+func fuseScore(mode string, detScore, clsScore, detectorWeight float64) float64 {
+	switch mode {
+	case "product":
+		return detScore * clsScore
+	case "min":
+		return math.Min(detScore, clsScore)
+	case "weighted_average":
+		return detectorWeight*detScore + (1-detectorWeight)*clsScore
+	default:
+		return clsScore
+	}
+}
+
+// filterStageResult applies a pipeline stage's min_confidence and label_filter
+// attributes, and prepends label_prefix (if any) to the surviving classifications
+// so downstream consumers can tell which pipeline stage produced each label.
+func filterStageResult(result classification.Classifications, stage pipelineStage) classification.Classifications {
+	var kept classification.Classifications
+	for _, c := range result {
+		if c.Score() < stage.minConfidence {
+			continue
+		}
+		if len(stage.labelFilter) > 0 && !slices.Contains(stage.labelFilter, c.Label()) {
+			continue
+		}
+		if stage.labelPrefix == "" {
+			kept = append(kept, c)
+			continue
+		}
+		kept = append(kept, classification.NewClassification(c.Score(), stage.labelPrefix+c.Label()))
+	}
+	return kept
+}
+
 func cropImage(img image.Image, rect *image.Rectangle) (image.Image, error) {
 	// The cropping operation is done by creating a new image of the size of the rectangle
 	// and drawing the relevant part of the original image onto the new image.
@@ -302,3 +900,102 @@ func saveImage(image image.Image, imagePath string) error {
 	jpeg.Encode(f, image, &opt)
 	return nil
 }
+
+// cropRingBuffer is a fixed-capacity ring of encoded JPEGs backing the
+// "dump_last_crops" DoCommand. A zero-capacity buffer silently discards adds.
+type cropRingBuffer struct {
+	mu   sync.Mutex
+	buf  [][]byte
+	next int
+	size int
+}
+
+func newCropRingBuffer(capacity int) *cropRingBuffer {
+	return &cropRingBuffer{buf: make([][]byte, capacity)}
+}
+
+// add stores jpegBytes as the most recent crop, overwriting the oldest once full.
+func (r *cropRingBuffer) add(jpegBytes []byte) {
+	if len(r.buf) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buf[r.next] = jpegBytes
+	r.next = (r.next + 1) % len(r.buf)
+	if r.size < len(r.buf) {
+		r.size++
+	}
+}
+
+// last returns up to n of the most-recently added crops, most recent first.
+func (r *cropRingBuffer) last(n int) [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	if n > r.size {
+		n = r.size
+	}
+	result := make([][]byte, 0, n)
+	idx := r.next - 1
+	for i := 0; i < n; i++ {
+		if idx < 0 {
+			idx += len(r.buf)
+		}
+		result = append(result, r.buf[idx])
+		idx--
+	}
+	return result
+}
+
+// latencyStats is a lightweight per-stage latency histogram backing the
+// "stats" DoCommand's p50/p95 fields. Each stage keeps a bounded window of its
+// most recent samples.
+type latencyStats struct {
+	mu      sync.Mutex
+	samples map[string][]time.Duration
+}
+
+const maxLatencySamples = 200
+
+func newLatencyStats() *latencyStats {
+	return &latencyStats{samples: make(map[string][]time.Duration)}
+}
+
+func (l *latencyStats) record(stage string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	samples := append(l.samples[stage], d)
+	if len(samples) > maxLatencySamples {
+		samples = samples[len(samples)-maxLatencySamples:]
+	}
+	l.samples[stage] = samples
+}
+
+// percentiles returns the p50 and p95 latency observed for stage, or zero if no samples exist.
+func (l *latencyStats) percentiles(stage string) (p50, p95 time.Duration) {
+	l.mu.Lock()
+	samples := append([]time.Duration(nil), l.samples[stage]...)
+	l.mu.Unlock()
+	if len(samples) == 0 {
+		return 0, 0
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	p50 = samples[(len(samples)-1)*50/100]
+	p95 = samples[(len(samples)-1)*95/100]
+	return p50, p95
+}
+
+// stageNames returns the names of all stages with recorded samples, sorted.
+func (l *latencyStats) stageNames() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	names := make([]string, 0, len(l.samples))
+	for name := range l.samples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}